@@ -0,0 +1,183 @@
+// Package registry resolves local Docker credentials for a given image's
+// registry and encodes them the way the engine API expects for private
+// registry pulls.
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// defaultRegistryHostname is the hostname Docker Hub images resolve to when
+// no registry is specified in the image reference.
+const defaultRegistryHostname = "https://index.docker.io/v1/"
+
+// AuthConfig is the JSON payload the engine API expects for registry
+// authentication, base64-encoded in the X-Registry-Auth header.
+type AuthConfig struct {
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	Auth          string `json:"auth,omitempty"`
+	Email         string `json:"email,omitempty"`
+	ServerAddress string `json:"serveraddress,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// configFile is the subset of ~/.docker/config.json this package reads.
+type configFile struct {
+	AuthConfigs map[string]struct {
+		Auth string `json:"auth"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// ResolveEncodedAuth resolves local Docker credentials for the registry
+// that hosts image, and returns them base64-encoded as swarmctl's
+// --with-registry-auth flag expects to pass to CreateService/UpdateService.
+// It returns an empty string, with no error, if no credentials are found for
+// the registry, matching an anonymous pull.
+func ResolveEncodedAuth(image string) (string, error) {
+	hostname := Hostname(image)
+
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return "", err
+	}
+
+	auth, err := resolveAuthConfig(cfg, hostname)
+	if err != nil {
+		return "", err
+	}
+	if auth == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode registry auth: %v", err)
+	}
+
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
+// Hostname returns the registry hostname referenced by image, defaulting to
+// Docker Hub's when the image has no explicit registry component.
+func Hostname(image string) string {
+	ref := image
+	if i := strings.IndexByte(ref, '@'); i != -1 {
+		ref = ref[:i]
+	}
+
+	slash := strings.IndexByte(ref, '/')
+	if slash == -1 {
+		return defaultRegistryHostname
+	}
+
+	host := ref[:slash]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		// A single path component with no dot, colon, or "localhost" is a
+		// Docker Hub repository namespace (e.g. "library/nginx"), not a
+		// registry hostname.
+		return defaultRegistryHostname
+	}
+
+	return host
+}
+
+func loadConfigFile() (*configFile, error) {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return nil, fmt.Errorf("failed to resolve home directory: $HOME is not set")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(home, ".docker", "config.json"))
+	if os.IsNotExist(err) {
+		return &configFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docker config.json: %v", err)
+	}
+
+	var cfg configFile
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config.json: %v", err)
+	}
+	return &cfg, nil
+}
+
+func resolveAuthConfig(cfg *configFile, hostname string) (*AuthConfig, error) {
+	if helper := cfg.CredHelpers[hostname]; helper != "" {
+		return authFromHelper(helper, hostname)
+	}
+	if cfg.CredsStore != "" {
+		return authFromHelper(cfg.CredsStore, hostname)
+	}
+
+	entry, ok := cfg.AuthConfigs[hostname]
+	if !ok || entry.Auth == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode auth for %s: %v", hostname, err)
+	}
+
+	userPass := strings.SplitN(string(decoded), ":", 2)
+	if len(userPass) != 2 {
+		return nil, fmt.Errorf("malformed auth entry for %s", hostname)
+	}
+
+	return &AuthConfig{
+		Username:      userPass[0],
+		Password:      userPass[1],
+		ServerAddress: hostname,
+	}, nil
+}
+
+// credHelperOutput is the JSON shape returned by `docker-credential-*  get`,
+// per the docker-credential-helpers protocol.
+type credHelperOutput struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+func authFromHelper(helper, hostname string) (*AuthConfig, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(hostname)
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		// Helpers report a missing entry on stdout with a non-zero exit.
+		if strings.TrimSpace(stdout.String()) == "credentials not found in native keychain" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("docker-credential-%s get failed for %s: %v", helper, hostname, err)
+	}
+
+	var out credHelperOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("failed to parse docker-credential-%s output: %v", helper, err)
+	}
+
+	if out.Username == "<token>" {
+		return &AuthConfig{IdentityToken: out.Secret, ServerAddress: hostname}, nil
+	}
+
+	return &AuthConfig{
+		Username:      out.Username,
+		Password:      out.Secret,
+		ServerAddress: hostname,
+	}, nil
+}
@@ -0,0 +1,22 @@
+package registry
+
+import "testing"
+
+func TestHostname(t *testing.T) {
+	for _, tc := range []struct {
+		image string
+		want  string
+	}{
+		{"nginx", defaultRegistryHostname},
+		{"nginx:latest", defaultRegistryHostname},
+		{"library/nginx", defaultRegistryHostname},
+		{"localhost/myimage", "localhost"},
+		{"localhost:5000/myimage", "localhost:5000"},
+		{"registry.example.com/myimage:1.0", "registry.example.com"},
+		{"registry.example.com:5000/team/myimage@sha256:abcd", "registry.example.com:5000"},
+	} {
+		if got := Hostname(tc.image); got != tc.want {
+			t.Errorf("Hostname(%q) = %q, want %q", tc.image, got, tc.want)
+		}
+	}
+}
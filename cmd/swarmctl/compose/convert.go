@@ -0,0 +1,339 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/swarmkit/api"
+)
+
+// NamespaceLabel is set on every object SwarmKit creates on behalf of a
+// stack so that `swarmctl stack` subcommands can filter by it.
+const NamespaceLabel = "com.docker.stack.namespace"
+
+// Converter turns a parsed Compose file into the SwarmKit specs needed to
+// deploy it under a single stack namespace.
+type Converter struct {
+	Namespace string
+}
+
+// NewConverter returns a Converter that labels every object it produces
+// with the given stack namespace.
+func NewConverter(namespace string) *Converter {
+	return &Converter{Namespace: namespace}
+}
+
+// ServiceName returns the namespaced name a compose service is created
+// under, e.g. "myapp_web" for service "web" in stack "myapp".
+func (c *Converter) ServiceName(name string) string {
+	return c.Namespace + "_" + name
+}
+
+// Services converts every service in cfg into an api.ServiceSpec, keyed by
+// its namespaced name. networkIDs maps each namespaced network name (as
+// returned by NetworkName) to the ID of the already-created or
+// already-existing network, so that network attachments reference the
+// network by ID rather than by name, consistent with how
+// `service create`/`service update` attach networks.
+func (c *Converter) Services(cfg *Config, networkIDs map[string]string) (map[string]*api.ServiceSpec, error) {
+	specs := make(map[string]*api.ServiceSpec, len(cfg.Services))
+	for name, svc := range cfg.Services {
+		spec, err := ConvertService(name, svc)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %v", name, err)
+		}
+
+		spec.Annotations.Name = c.ServiceName(name)
+		spec.Annotations.Labels[NamespaceLabel] = c.Namespace
+
+		netNames := make([]string, 0, len(svc.Networks))
+		for netName := range svc.Networks {
+			netNames = append(netNames, netName)
+		}
+		sort.Strings(netNames)
+
+		for _, netName := range netNames {
+			namespacedName := c.NetworkName(netName)
+			id, ok := networkIDs[namespacedName]
+			if !ok {
+				return nil, fmt.Errorf("service %s: network %s has no known ID", name, netName)
+			}
+			spec.Networks = append(spec.Networks, &api.ServiceSpec_NetworkAttachmentConfig{
+				Target: id,
+			})
+		}
+
+		for _, m := range spec.Task.GetContainer().Mounts {
+			if m.Type != api.MountTypeVolume {
+				continue
+			}
+			if v, ok := cfg.Volumes[m.Source]; ok && v.External.External {
+				continue
+			}
+			m.Source = c.VolumeName(m.Source)
+		}
+
+		specs[c.ServiceName(name)] = spec
+	}
+	return specs, nil
+}
+
+// ConvertService converts a single Compose service into an api.ServiceSpec
+// named exactly `name`, with no stack namespace applied. It is the building
+// block Converter.Services uses internally, and is also reused by
+// `swarmctl service create -f` to convert a single-service Compose file
+// without standing up a full stack.
+func ConvertService(name string, svc Service) (*api.ServiceSpec, error) {
+	ports, err := convertPorts(svc.Ports)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts, err := convertMounts(svc.Volumes)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{}
+	for k, v := range svc.Deploy.Labels {
+		labels[k] = v
+	}
+
+	spec := &api.ServiceSpec{
+		Annotations: api.Annotations{
+			Name:   name,
+			Labels: labels,
+		},
+		Task: api.TaskSpec{
+			Runtime: &api.TaskSpec_Container{
+				Container: &api.ContainerSpec{
+					Image:   svc.Image,
+					Command: svc.Entrypoint,
+					Args:    svc.Command,
+					Env:     convertEnv(svc.Environment),
+					Mounts:  mounts,
+				},
+			},
+			Placement: &api.Placement{
+				Constraints: svc.Deploy.Placement.Constraints,
+			},
+			Restart: convertRestartPolicy(svc.Deploy.RestartPolicy),
+		},
+		Update: convertUpdateConfig(svc.Deploy.UpdateConfig),
+	}
+
+	if len(ports) > 0 {
+		spec.Endpoint = &api.EndpointSpec{ExposedPorts: ports}
+	}
+
+	switch svc.Deploy.Mode {
+	case "global":
+		spec.Mode = &api.ServiceSpec_Global{}
+	default:
+		replicas := uint64(1)
+		if svc.Deploy.Replicas != nil {
+			replicas = *svc.Deploy.Replicas
+		}
+		spec.Mode = &api.ServiceSpec_Replicated{
+			Replicated: &api.ReplicatedService{Instances: replicas},
+		}
+	}
+
+	return spec, nil
+}
+
+// VolumeName returns the namespaced name a compose top-level volume is
+// created under, e.g. "myapp_data" for volume "data" in stack "myapp".
+func (c *Converter) VolumeName(name string) string {
+	return c.Namespace + "_" + name
+}
+
+// NetworkName returns the namespaced name a compose network is created
+// under, e.g. "myapp_default" for network "default" in stack "myapp".
+func (c *Converter) NetworkName(name string) string {
+	return c.Namespace + "_" + name
+}
+
+// Networks converts every non-external network in cfg into an
+// api.NetworkSpec, keyed by its namespaced name. Networks marked
+// `external: true` are skipped; callers are expected to resolve those by
+// name instead of creating them.
+func (c *Converter) Networks(cfg *Config) (map[string]*api.NetworkSpec, error) {
+	specs := make(map[string]*api.NetworkSpec, len(cfg.Networks))
+	for name, n := range cfg.Networks {
+		if n.External.External {
+			continue
+		}
+
+		driver := n.Driver
+		if driver == "" {
+			driver = "overlay"
+		}
+
+		spec := &api.NetworkSpec{
+			Annotations: api.Annotations{
+				Name:   c.NetworkName(name),
+				Labels: map[string]string{NamespaceLabel: c.Namespace},
+			},
+			DriverConfig: &api.Driver{
+				Name:    driver,
+				Options: n.DriverOpts,
+			},
+		}
+
+		if len(n.Ipam.Config) > 0 {
+			spec.IPAM = &api.IPAMOptions{Driver: &api.Driver{Name: n.Ipam.Driver}}
+			for _, block := range n.Ipam.Config {
+				spec.IPAM.Configs = append(spec.IPAM.Configs, &api.IPAMConfig{Subnet: block.Subnet})
+			}
+		}
+
+		specs[c.NetworkName(name)] = spec
+	}
+	return specs, nil
+}
+
+func convertEnv(env map[string]string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, 0, len(env))
+	for _, k := range keys {
+		out = append(out, k+"="+env[k])
+	}
+	return out
+}
+
+func convertRestartPolicy(rp RestartPolicy) *api.RestartPolicy {
+	switch rp.Condition {
+	case "none":
+		return &api.RestartPolicy{Condition: api.RestartOnNone}
+	case "any", "":
+		return &api.RestartPolicy{Condition: api.RestartOnAny}
+	case "on-failure":
+		return &api.RestartPolicy{Condition: api.RestartOnFailure}
+	default:
+		return &api.RestartPolicy{Condition: api.RestartOnAny}
+	}
+}
+
+func convertUpdateConfig(uc UpdateConfig) *api.UpdateConfig {
+	if uc.Parallelism == 0 && uc.Delay == "" {
+		return nil
+	}
+
+	update := &api.UpdateConfig{Parallelism: uc.Parallelism}
+	if uc.Delay != "" {
+		d, err := time.ParseDuration(uc.Delay)
+		if err == nil {
+			update.Delay = d
+		}
+	}
+	return update
+}
+
+// convertMounts parses Compose's short volume syntax, "source:target[:ro]",
+// into api.Mount entries. A source starting with "/", "./", "../" or "~"
+// is treated as a bind mount of a host path; anything else is treated as a
+// named volume, which Converter.Services namespaces to the stack unless
+// the volume is declared `external: true`. Compose's long form is left for
+// a follow-up.
+func convertMounts(volumes []string) ([]*api.Mount, error) {
+	var out []*api.Mount
+	for _, raw := range volumes {
+		parts := strings.Split(raw, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid volume %q", raw)
+		}
+
+		source, target := parts[0], parts[1]
+		if source == "" || target == "" {
+			return nil, fmt.Errorf("invalid volume %q", raw)
+		}
+
+		readOnly := false
+		if len(parts) == 3 {
+			switch parts[2] {
+			case "ro":
+				readOnly = true
+			case "rw":
+				readOnly = false
+			default:
+				return nil, fmt.Errorf("invalid volume mode %q in %q", parts[2], raw)
+			}
+		}
+
+		mountType := api.MountTypeVolume
+		if isBindSource(source) {
+			mountType = api.MountTypeBind
+		}
+
+		out = append(out, &api.Mount{
+			Type:     mountType,
+			Source:   source,
+			Target:   target,
+			ReadOnly: readOnly,
+		})
+	}
+	return out, nil
+}
+
+func isBindSource(source string) bool {
+	return strings.HasPrefix(source, "/") ||
+		strings.HasPrefix(source, "./") ||
+		strings.HasPrefix(source, "../") ||
+		strings.HasPrefix(source, "~")
+}
+
+// convertPorts parses Compose's short port syntax, "published:target[/proto]",
+// into api.PortConfig entries. Compose's long form is left for a follow-up.
+func convertPorts(ports []string) ([]*api.PortConfig, error) {
+	var out []*api.PortConfig
+	for _, raw := range ports {
+		proto := api.ProtocolTCP
+		spec := raw
+		if idx := strings.LastIndex(spec, "/"); idx != -1 {
+			p, ok := api.PortConfig_Protocol_value[strings.ToUpper(spec[idx+1:])]
+			if !ok {
+				return nil, fmt.Errorf("invalid protocol in port %q", raw)
+			}
+			proto = api.PortConfig_Protocol(p)
+			spec = spec[:idx]
+		}
+
+		parts := strings.Split(spec, ":")
+		var published, target uint64
+		var err error
+		switch len(parts) {
+		case 1:
+			target, err = strconv.ParseUint(parts[0], 10, 32)
+		case 2:
+			published, err = strconv.ParseUint(parts[0], 10, 32)
+			if err == nil {
+				target, err = strconv.ParseUint(parts[1], 10, 32)
+			}
+		default:
+			return nil, fmt.Errorf("invalid port %q", raw)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q: %v", raw, err)
+		}
+
+		out = append(out, &api.PortConfig{
+			Protocol:  proto,
+			Port:      uint32(target),
+			SwarmPort: uint32(published),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,220 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/docker/swarmkit/api"
+)
+
+func TestConverterServices(t *testing.T) {
+	cfg, err := Load([]byte(`
+version: "3"
+services:
+  web:
+    image: nginx:latest
+    ports:
+      - "8080:80/tcp"
+    environment:
+      FOO: bar
+    deploy:
+      replicas: 3
+  worker:
+    image: worker:latest
+    deploy:
+      mode: global
+`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	c := NewConverter("mystack")
+	specs, err := c.Services(cfg, nil)
+	if err != nil {
+		t.Fatalf("Services failed: %v", err)
+	}
+
+	web, ok := specs["mystack_web"]
+	if !ok {
+		t.Fatal("expected mystack_web service spec")
+	}
+	if web.Annotations.Labels[NamespaceLabel] != "mystack" {
+		t.Fatalf("expected namespace label, got %v", web.Annotations.Labels)
+	}
+	container := web.Task.GetContainer()
+	if container.Image != "nginx:latest" {
+		t.Fatalf("expected image nginx:latest, got %s", container.Image)
+	}
+	if len(web.Endpoint.ExposedPorts) != 1 {
+		t.Fatalf("expected 1 port, got %d", len(web.Endpoint.ExposedPorts))
+	}
+	port := web.Endpoint.ExposedPorts[0]
+	if port.Port != 80 || port.SwarmPort != 8080 || port.Protocol != api.ProtocolTCP {
+		t.Fatalf("unexpected port conversion: %+v", port)
+	}
+	replicated := web.Mode.(*api.ServiceSpec_Replicated)
+	if replicated.Replicated.Instances != 3 {
+		t.Fatalf("expected 3 replicas, got %d", replicated.Replicated.Instances)
+	}
+
+	worker, ok := specs["mystack_worker"]
+	if !ok {
+		t.Fatal("expected mystack_worker service spec")
+	}
+	if _, ok := worker.Mode.(*api.ServiceSpec_Global); !ok {
+		t.Fatalf("expected global mode, got %T", worker.Mode)
+	}
+}
+
+func TestConverterServicesNetworkAttachments(t *testing.T) {
+	cfg, err := Load([]byte(`
+version: "3"
+services:
+  web:
+    image: nginx:latest
+    networks:
+      front: {}
+`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	c := NewConverter("mystack")
+
+	if _, err := c.Services(cfg, nil); err == nil {
+		t.Fatal("expected error when network ID is unknown")
+	}
+
+	networkIDs := map[string]string{c.NetworkName("front"): "abc123"}
+	specs, err := c.Services(cfg, networkIDs)
+	if err != nil {
+		t.Fatalf("Services failed: %v", err)
+	}
+
+	web := specs["mystack_web"]
+	if len(web.Networks) != 1 || web.Networks[0].Target != "abc123" {
+		t.Fatalf("expected network attachment by ID, got %+v", web.Networks)
+	}
+}
+
+func TestConverterNetworksSkipsExternal(t *testing.T) {
+	cfg, err := Load([]byte(`
+version: "3"
+services:
+  web:
+    image: nginx:latest
+networks:
+  front:
+    driver: overlay
+  legacy:
+    external: true
+`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	c := NewConverter("mystack")
+	specs, err := c.Networks(cfg)
+	if err != nil {
+		t.Fatalf("Networks failed: %v", err)
+	}
+
+	if _, ok := specs["mystack_front"]; !ok {
+		t.Fatal("expected mystack_front network spec")
+	}
+	if _, ok := specs["mystack_legacy"]; ok {
+		t.Fatal("external network should not be converted")
+	}
+}
+
+func TestConvertPortsInvalid(t *testing.T) {
+	if _, err := convertPorts([]string{"not-a-port"}); err == nil {
+		t.Fatal("expected error for invalid port")
+	}
+	if _, err := convertPorts([]string{"80/bogus"}); err == nil {
+		t.Fatal("expected error for invalid protocol")
+	}
+}
+
+func TestConvertMounts(t *testing.T) {
+	mounts, err := convertMounts([]string{
+		"/host/data:/data",
+		"./relative:/relative:ro",
+		"named-volume:/var/lib/data:ro",
+	})
+	if err != nil {
+		t.Fatalf("convertMounts failed: %v", err)
+	}
+	if len(mounts) != 3 {
+		t.Fatalf("expected 3 mounts, got %d", len(mounts))
+	}
+
+	bind := mounts[0]
+	if bind.Type != api.MountTypeBind || bind.Source != "/host/data" || bind.Target != "/data" || bind.ReadOnly {
+		t.Fatalf("unexpected bind mount: %+v", bind)
+	}
+
+	relative := mounts[1]
+	if relative.Type != api.MountTypeBind || !relative.ReadOnly {
+		t.Fatalf("unexpected relative bind mount: %+v", relative)
+	}
+
+	volume := mounts[2]
+	if volume.Type != api.MountTypeVolume || volume.Source != "named-volume" || !volume.ReadOnly {
+		t.Fatalf("unexpected volume mount: %+v", volume)
+	}
+}
+
+func TestConvertMountsInvalid(t *testing.T) {
+	if _, err := convertMounts([]string{"no-target"}); err == nil {
+		t.Fatal("expected error for missing target")
+	}
+	if _, err := convertMounts([]string{"src:dst:bogus"}); err == nil {
+		t.Fatal("expected error for invalid mode")
+	}
+}
+
+func TestConverterServicesNamespacesVolumeMounts(t *testing.T) {
+	cfg, err := Load([]byte(`
+version: "3"
+services:
+  web:
+    image: nginx:latest
+    volumes:
+      - "data:/var/lib/data"
+      - "cache:/var/lib/cache"
+      - "/host/path:/host"
+volumes:
+  data: {}
+  cache:
+    external: true
+`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	c := NewConverter("mystack")
+	specs, err := c.Services(cfg, nil)
+	if err != nil {
+		t.Fatalf("Services failed: %v", err)
+	}
+
+	mounts := specs["mystack_web"].Task.GetContainer().Mounts
+	if len(mounts) != 3 {
+		t.Fatalf("expected 3 mounts, got %d", len(mounts))
+	}
+
+	byTarget := map[string]*api.Mount{}
+	for _, m := range mounts {
+		byTarget[m.Target] = m
+	}
+
+	if got := byTarget["/var/lib/data"].Source; got != "mystack_data" {
+		t.Fatalf("expected namespaced volume source, got %s", got)
+	}
+	if got := byTarget["/var/lib/cache"].Source; got != "cache" {
+		t.Fatalf("expected external volume source left unnamespaced, got %s", got)
+	}
+	if got := byTarget["/host"].Source; got != "/host/path" {
+		t.Fatalf("expected bind mount source untouched, got %s", got)
+	}
+}
@@ -0,0 +1,32 @@
+package compose
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// LoadFile reads and parses a Compose v3 file from disk.
+func LoadFile(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose file: %v", err)
+	}
+
+	return Load(data)
+}
+
+// Load parses Compose v3 YAML from an in-memory buffer.
+func Load(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse compose file: %v", err)
+	}
+
+	if cfg.Services == nil {
+		return nil, fmt.Errorf("compose file declares no services")
+	}
+
+	return &cfg, nil
+}
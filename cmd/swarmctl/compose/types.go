@@ -0,0 +1,124 @@
+package compose
+
+import "strings"
+
+// Config is the parsed representation of a Compose v3 file: the pieces the
+// stack converter cares about, not a full schema of every Compose key.
+type Config struct {
+	Version  string             `yaml:"version"`
+	Services map[string]Service `yaml:"services"`
+	Networks map[string]Network `yaml:"networks"`
+	Volumes  map[string]Volume  `yaml:"volumes"`
+}
+
+// Service is a single entry under the top-level `services` key.
+type Service struct {
+	Image       string            `yaml:"image"`
+	Command     StringOrSlice     `yaml:"command"`
+	Entrypoint  StringOrSlice     `yaml:"entrypoint"`
+	Environment map[string]string `yaml:"environment"`
+	Ports       []string          `yaml:"ports"`
+	Volumes     []string          `yaml:"volumes"`
+	Networks    map[string]struct {
+		Aliases []string `yaml:"aliases"`
+	} `yaml:"networks"`
+	Deploy Deploy `yaml:"deploy"`
+}
+
+// Deploy captures the `deploy` key of a Compose v3 service: the subset of
+// fields that have a direct SwarmKit equivalent.
+type Deploy struct {
+	Mode          string            `yaml:"mode"`
+	Replicas      *uint64           `yaml:"replicas"`
+	Labels        map[string]string `yaml:"labels"`
+	Placement     Placement         `yaml:"placement"`
+	UpdateConfig  UpdateConfig      `yaml:"update_config"`
+	RestartPolicy RestartPolicy     `yaml:"restart_policy"`
+}
+
+// Placement is the `deploy.placement` key.
+type Placement struct {
+	Constraints []string `yaml:"constraints"`
+}
+
+// UpdateConfig is the `deploy.update_config` key.
+type UpdateConfig struct {
+	Parallelism uint64 `yaml:"parallelism"`
+	Delay       string `yaml:"delay"`
+}
+
+// RestartPolicy is the `deploy.restart_policy` key.
+type RestartPolicy struct {
+	Condition string `yaml:"condition"`
+}
+
+// Network is a single entry under the top-level `networks` key.
+type Network struct {
+	Driver     string            `yaml:"driver"`
+	DriverOpts map[string]string `yaml:"driver_opts"`
+	Ipam       Ipam              `yaml:"ipam"`
+	External   External          `yaml:"external"`
+}
+
+// Ipam is the `networks.<name>.ipam` key.
+type Ipam struct {
+	Driver string      `yaml:"driver"`
+	Config []IpamBlock `yaml:"config"`
+}
+
+// IpamBlock is a single entry in `networks.<name>.ipam.config`.
+type IpamBlock struct {
+	Subnet string `yaml:"subnet"`
+}
+
+// External marks a network or volume as managed outside the stack.
+type External struct {
+	Name     string `yaml:"name"`
+	External bool   `yaml:"external"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. Compose writes `external` either
+// as a bool or as a mapping with a `name` key; the mapping form sets External.
+func (e *External) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var b bool
+	if err := unmarshal(&b); err == nil {
+		e.External = b
+		return nil
+	}
+
+	var m struct {
+		Name string `yaml:"name"`
+	}
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	e.Name, e.External = m.Name, true
+	return nil
+}
+
+// Volume is a single entry under the top-level `volumes` key.
+type Volume struct {
+	Driver     string            `yaml:"driver"`
+	DriverOpts map[string]string `yaml:"driver_opts"`
+	External   External          `yaml:"external"`
+}
+
+// StringOrSlice unmarshals either a YAML scalar or a sequence of strings,
+// which Compose allows interchangeably for `command` and `entrypoint`.
+type StringOrSlice []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *StringOrSlice) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var multi []string
+	if err := unmarshal(&multi); err == nil {
+		*s = multi
+		return nil
+	}
+
+	var single string
+	if err := unmarshal(&single); err != nil {
+		return err
+	}
+	*s = StringOrSlice(strings.Fields(single))
+	return nil
+}
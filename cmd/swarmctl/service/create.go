@@ -3,13 +3,15 @@ package service
 import (
 	"errors"
 	"fmt"
-	"strconv"
-	"strings"
 
 	"github.com/docker/swarmkit/api"
 	"github.com/docker/swarmkit/cmd/swarmctl/common"
+	"github.com/docker/swarmkit/cmd/swarmctl/common/registry"
+	"github.com/docker/swarmkit/cmd/swarmctl/compose"
 	"github.com/docker/swarmkit/cmd/swarmctl/network"
+	"github.com/docker/swarmkit/cmd/swarmctl/service/serviceopts"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
@@ -25,6 +27,33 @@ var (
 				return err
 			}
 
+			if flags.Changed("file") {
+				file, err := flags.GetString("file")
+				if err != nil {
+					return err
+				}
+
+				spec, err := serviceSpecFromComposeFile(file)
+				if err != nil {
+					return err
+				}
+
+				encodedAuth, err := resolveRegistryAuth(flags, spec.Task.GetContainer().Image)
+				if err != nil {
+					return err
+				}
+
+				r, err := c.CreateService(common.Context(cmd), &api.CreateServiceRequest{
+					Spec:                spec,
+					EncodedRegistryAuth: encodedAuth,
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Println(r.Service.ID)
+				return nil
+			}
+
 			if !flags.Changed("name") || !flags.Changed("image") {
 				return errors.New("--name and --image are mandatory")
 			}
@@ -85,25 +114,20 @@ var (
 				}
 			}
 
-			if flags.Changed("ports") {
-				portConfigs, err := flags.GetStringSlice("ports")
+			if flags.Changed("publish") {
+				portConfigs, err := flags.GetStringSlice("publish")
 				if err != nil {
 					return err
 				}
 
 				ports := []*api.PortConfig{}
 				for _, portConfig := range portConfigs {
-					name, protocol, port, swarmPort, err := parsePortConfig(portConfig)
+					port, err := serviceopts.ParsePort(portConfig)
 					if err != nil {
 						return err
 					}
 
-					ports = append(ports, &api.PortConfig{
-						Name:      name,
-						Protocol:  protocol,
-						Port:      port,
-						SwarmPort: swarmPort,
-					})
+					ports = append(ports, port)
 				}
 
 				spec.Endpoint = &api.EndpointSpec{
@@ -129,7 +153,15 @@ var (
 				}
 			}
 
-			r, err := c.CreateService(common.Context(cmd), &api.CreateServiceRequest{Spec: spec})
+			encodedAuth, err := resolveRegistryAuth(flags, image)
+			if err != nil {
+				return err
+			}
+
+			r, err := c.CreateService(common.Context(cmd), &api.CreateServiceRequest{
+				Spec:                spec,
+				EncodedRegistryAuth: encodedAuth,
+			})
 			if err != nil {
 				return err
 			}
@@ -139,59 +171,39 @@ var (
 	}
 )
 
-func parsePortConfig(portConfig string) (string, api.PortConfig_Protocol, uint32, uint32, error) {
-	protocol := api.ProtocolTCP
-	parts := strings.Split(portConfig, ":")
-	if len(parts) < 2 {
-		return "", protocol, 0, 0, fmt.Errorf("insuffient parameters in port configuration")
-	}
-
-	name := parts[0]
-
-	portSpec := parts[1]
-	protocol, port, err := parsePortSpec(portSpec)
+// resolveRegistryAuth resolves the local Docker credentials for image's
+// registry when --with-registry-auth is set, so the manager can forward
+// them to agents for private-registry pulls. It returns an empty string
+// when the flag isn't set.
+func resolveRegistryAuth(flags *pflag.FlagSet, image string) (string, error) {
+	withAuth, err := flags.GetBool("with-registry-auth")
 	if err != nil {
-		return "", protocol, 0, 0, fmt.Errorf("failed to parse port: %v", err)
+		return "", err
 	}
-
-	if len(parts) > 2 {
-		var err error
-
-		portSpec := parts[2]
-		nodeProtocol, swarmPort, err := parsePortSpec(portSpec)
-		if err != nil {
-			return "", protocol, 0, 0, fmt.Errorf("failed to parse node port: %v", err)
-		}
-
-		if nodeProtocol != protocol {
-			return "", protocol, 0, 0, fmt.Errorf("protocol mismatch")
-		}
-
-		return name, protocol, port, swarmPort, nil
+	if !withAuth {
+		return "", nil
 	}
 
-	return name, protocol, port, 0, nil
+	return registry.ResolveEncodedAuth(image)
 }
 
-func parsePortSpec(portSpec string) (api.PortConfig_Protocol, uint32, error) {
-	parts := strings.Split(portSpec, "/")
-	p := parts[0]
-	port, err := strconv.ParseUint(p, 10, 32)
+// serviceSpecFromComposeFile converts a single-service Compose v3 file
+// given via `-f` into an api.ServiceSpec, as a shortcut for users who don't
+// need a full `swarmctl stack deploy`.
+func serviceSpecFromComposeFile(file string) (*api.ServiceSpec, error) {
+	cfg, err := compose.LoadFile(file)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 
-	if len(parts) > 1 {
-		proto := parts[1]
-		protocol, ok := api.PortConfig_Protocol_value[strings.ToUpper(proto)]
-		if !ok {
-			return 0, 0, fmt.Errorf("invalid protocol string: %s", proto)
-		}
-
-		return api.PortConfig_Protocol(protocol), uint32(port), nil
+	if len(cfg.Services) != 1 {
+		return nil, fmt.Errorf("-f only supports compose files with a single service, found %d", len(cfg.Services))
 	}
 
-	return api.ProtocolTCP, uint32(port), nil
+	for name, svc := range cfg.Services {
+		return compose.ConvertService(name, svc)
+	}
+	panic("unreachable")
 }
 
 func init() {
@@ -199,8 +211,9 @@ func init() {
 	createCmd.Flags().String("image", "", "Image")
 	createCmd.Flags().StringSlice("args", nil, "Args")
 	createCmd.Flags().StringSlice("env", nil, "Env")
-	createCmd.Flags().StringSlice("ports", nil, "Ports")
+	createCmd.Flags().StringSlice("publish", nil, "Publish a port as name:port[/protocol][:swarmPort], or target=,published=,protocol=,mode=,name=")
 	createCmd.Flags().StringP("file", "f", "", "Spec to use")
+	createCmd.Flags().Bool("with-registry-auth", false, "Send registry authentication details to swarm agents")
 	createCmd.Flags().String("network", "", "Network name")
 	// TODO(aluzzardi): This should be called `service-instances` so that every
 	// orchestrator can have its own flag namespace.
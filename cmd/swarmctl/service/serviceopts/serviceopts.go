@@ -0,0 +1,289 @@
+// Package serviceopts holds the mutation helpers shared by `swarmctl
+// service create` and `swarmctl service update`, so that both commands
+// parse and apply their list flags (--publish, --env, --network,
+// --constraint, --label, ...) identically.
+package serviceopts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/swarmkit/api"
+)
+
+// ParsePort accepts either the legacy colon-delimited form,
+// "name:port[/proto][:swarmPort]", or the CSV key=value form used by the
+// Docker CLI's PortOpt, e.g. "target=80,published=8080,protocol=tcp,mode=ingress".
+// The two are told apart by the presence of "=" in the input.
+func ParsePort(raw string) (*api.PortConfig, error) {
+	if strings.Contains(raw, "=") {
+		return parsePortCSV(raw)
+	}
+	return parsePortLegacy(raw)
+}
+
+func parsePortLegacy(raw string) (*api.PortConfig, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("insuffient parameters in port configuration")
+	}
+
+	name := parts[0]
+
+	protocol, port, err := parsePortSpec(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse port: %v", err)
+	}
+
+	var swarmPort uint32
+	if len(parts) > 2 {
+		nodeProtocol, parsedSwarmPort, err := parsePortSpec(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse node port: %v", err)
+		}
+		if nodeProtocol != protocol {
+			return nil, fmt.Errorf("protocol mismatch")
+		}
+		swarmPort = parsedSwarmPort
+	}
+
+	return &api.PortConfig{
+		Name:      name,
+		Protocol:  protocol,
+		Port:      port,
+		SwarmPort: swarmPort,
+	}, nil
+}
+
+// validPortKeys are the keys recognized in the CSV form of --publish.
+var validPortKeys = map[string]bool{
+	"target":    true,
+	"published": true,
+	"protocol":  true,
+	"mode":      true,
+	"name":      true,
+}
+
+func parsePortCSV(raw string) (*api.PortConfig, error) {
+	fields := map[string]string{}
+	for _, field := range strings.Split(raw, ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid field in port configuration: %s", field)
+		}
+
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		if !validPortKeys[key] {
+			return nil, fmt.Errorf("invalid key %q in port configuration", key)
+		}
+		if _, ok := fields[key]; ok {
+			return nil, fmt.Errorf("duplicate key %q in port configuration", key)
+		}
+
+		fields[key] = strings.TrimSpace(kv[1])
+	}
+
+	if fields["target"] == "" {
+		return nil, fmt.Errorf("target is required in port configuration")
+	}
+
+	target, err := strconv.ParseUint(fields["target"], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target port: %v", err)
+	}
+
+	var published uint64
+	if fields["published"] != "" {
+		published, err = strconv.ParseUint(fields["published"], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid published port: %v", err)
+		}
+	}
+
+	protocol := api.ProtocolTCP
+	if proto, ok := fields["protocol"]; ok {
+		p, ok := api.PortConfig_Protocol_value[strings.ToUpper(proto)]
+		if !ok {
+			return nil, fmt.Errorf("invalid protocol string: %s", proto)
+		}
+		protocol = api.PortConfig_Protocol(p)
+	}
+
+	mode := api.PublishModeIngress
+	if m, ok := fields["mode"]; ok {
+		parsedMode, ok := api.PortConfig_PublishMode_value[strings.ToUpper(m)]
+		if !ok {
+			return nil, fmt.Errorf("invalid publish mode: %s", m)
+		}
+		mode = api.PortConfig_PublishMode(parsedMode)
+	}
+
+	return &api.PortConfig{
+		Name:        fields["name"],
+		Protocol:    protocol,
+		Port:        uint32(target),
+		SwarmPort:   uint32(published),
+		PublishMode: mode,
+	}, nil
+}
+
+func parsePortSpec(raw string) (api.PortConfig_Protocol, uint32, error) {
+	parts := strings.Split(raw, "/")
+	port, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(parts) > 1 {
+		protocol, ok := api.PortConfig_Protocol_value[strings.ToUpper(parts[1])]
+		if !ok {
+			return 0, 0, fmt.Errorf("invalid protocol string: %s", parts[1])
+		}
+		return api.PortConfig_Protocol(protocol), uint32(port), nil
+	}
+
+	return api.ProtocolTCP, uint32(port), nil
+}
+
+// AddPort appends port to ports, replacing any existing entry that
+// publishes the same target+protocol.
+func AddPort(ports []*api.PortConfig, port *api.PortConfig) []*api.PortConfig {
+	out := RemovePortTuple(ports, port.Port, port.Protocol)
+	return append(out, port)
+}
+
+// RemovePort parses raw as "target[/protocol]" and removes the matching
+// port from ports, as used by --publish-rm.
+func RemovePort(ports []*api.PortConfig, raw string) ([]*api.PortConfig, error) {
+	protocol, target, err := parsePortSpec(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q: %v", raw, err)
+	}
+	return RemovePortTuple(ports, target, protocol), nil
+}
+
+// RemovePortTuple removes every port in ports published on target+protocol.
+func RemovePortTuple(ports []*api.PortConfig, target uint32, protocol api.PortConfig_Protocol) []*api.PortConfig {
+	out := make([]*api.PortConfig, 0, len(ports))
+	for _, p := range ports {
+		if p.Port == target && p.Protocol == protocol {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// AddEnv parses raw as "KEY=VALUE" and sets it in env, replacing any
+// existing entry for the same key.
+func AddEnv(env []string, raw string) ([]string, error) {
+	key, _, ok := splitKV(raw)
+	if !ok {
+		return nil, fmt.Errorf("invalid env entry %q, expected KEY=VALUE", raw)
+	}
+	out := RemoveEnv(env, key)
+	return append(out, raw), nil
+}
+
+// RemoveEnv removes the entry for key from env, if present.
+func RemoveEnv(env []string, key string) []string {
+	out := make([]string, 0, len(env))
+	for _, e := range env {
+		k, _, ok := splitKV(e)
+		if ok && k == key {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func splitKV(raw string) (string, string, bool) {
+	parts := strings.SplitN(raw, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// AddNetwork attaches targetID to networks, unless it is already attached.
+func AddNetwork(networks []*api.ServiceSpec_NetworkAttachmentConfig, targetID string) []*api.ServiceSpec_NetworkAttachmentConfig {
+	for _, n := range networks {
+		if n.Target == targetID {
+			return networks
+		}
+	}
+	return append(networks, &api.ServiceSpec_NetworkAttachmentConfig{Target: targetID})
+}
+
+// RemoveNetwork detaches targetID from networks, if attached.
+func RemoveNetwork(networks []*api.ServiceSpec_NetworkAttachmentConfig, targetID string) []*api.ServiceSpec_NetworkAttachmentConfig {
+	out := make([]*api.ServiceSpec_NetworkAttachmentConfig, 0, len(networks))
+	for _, n := range networks {
+		if n.Target == targetID {
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// AddConstraint appends raw (e.g. "node.role==manager") to constraints,
+// replacing any existing constraint on the same key.
+func AddConstraint(constraints []string, raw string) ([]string, error) {
+	key, err := constraintKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	out := removeConstraintKey(constraints, key)
+	return append(out, raw), nil
+}
+
+// RemoveConstraint removes the constraint on raw's key from constraints.
+func RemoveConstraint(constraints []string, raw string) ([]string, error) {
+	key, err := constraintKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	return removeConstraintKey(constraints, key), nil
+}
+
+func removeConstraintKey(constraints []string, key string) []string {
+	out := make([]string, 0, len(constraints))
+	for _, c := range constraints {
+		if k, err := constraintKey(c); err == nil && k == key {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func constraintKey(raw string) (string, error) {
+	for _, op := range []string{"!=", "=="} {
+		if idx := strings.Index(raw, op); idx != -1 {
+			return raw[:idx], nil
+		}
+	}
+	return "", fmt.Errorf("invalid constraint %q, expected key==value or key!=value", raw)
+}
+
+// AddLabel parses raw as "KEY=VALUE" and sets it in labels.
+func AddLabel(labels map[string]string, raw string) (map[string]string, error) {
+	key, value, ok := splitKV(raw)
+	if !ok {
+		return nil, fmt.Errorf("invalid label %q, expected KEY=VALUE", raw)
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[key] = value
+	return labels, nil
+}
+
+// RemoveLabel removes key from labels, if present.
+func RemoveLabel(labels map[string]string, key string) map[string]string {
+	delete(labels, key)
+	return labels
+}
@@ -0,0 +1,203 @@
+package serviceopts
+
+import (
+	"testing"
+
+	"github.com/docker/swarmkit/api"
+)
+
+func TestParsePort(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		input   string
+		want    *api.PortConfig
+		wantErr bool
+	}{
+		{
+			name:  "legacy form",
+			input: "web:80/tcp:8080/tcp",
+			want: &api.PortConfig{
+				Name:      "web",
+				Protocol:  api.ProtocolTCP,
+				Port:      80,
+				SwarmPort: 8080,
+			},
+		},
+		{
+			name:  "legacy form without swarm port",
+			input: "web:80",
+			want: &api.PortConfig{
+				Name:     "web",
+				Protocol: api.ProtocolTCP,
+				Port:     80,
+			},
+		},
+		{
+			name:    "legacy form with protocol mismatch",
+			input:   "web:80/tcp:8080/udp",
+			wantErr: true,
+		},
+		{
+			name:  "csv form",
+			input: "target=80,published=8080,protocol=tcp,mode=ingress,name=web",
+			want: &api.PortConfig{
+				Name:        "web",
+				Protocol:    api.ProtocolTCP,
+				Port:        80,
+				SwarmPort:   8080,
+				PublishMode: api.PublishModeIngress,
+			},
+		},
+		{
+			name:  "csv form defaults protocol and mode",
+			input: "target=80",
+			want: &api.PortConfig{
+				Protocol:    api.ProtocolTCP,
+				Port:        80,
+				PublishMode: api.PublishModeIngress,
+			},
+		},
+		{
+			name:  "csv form host mode",
+			input: "target=80,mode=host",
+			want: &api.PortConfig{
+				Protocol:    api.ProtocolTCP,
+				Port:        80,
+				PublishMode: api.PublishModeHost,
+			},
+		},
+		{
+			name:    "csv form missing target",
+			input:   "published=8080",
+			wantErr: true,
+		},
+		{
+			name:    "csv form unknown key",
+			input:   "target=80,bogus=1",
+			wantErr: true,
+		},
+		{
+			name:    "csv form invalid protocol",
+			input:   "target=80,protocol=bogus",
+			wantErr: true,
+		},
+		{
+			name:    "csv form invalid mode",
+			input:   "target=80,mode=bogus",
+			wantErr: true,
+		},
+		{
+			name:    "csv form duplicate key",
+			input:   "target=80,target=81",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParsePort(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != *tc.want {
+				t.Fatalf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAddRemovePort(t *testing.T) {
+	ports := []*api.PortConfig{{Port: 80, Protocol: api.ProtocolTCP}}
+
+	updated := AddPort(ports, &api.PortConfig{Port: 80, Protocol: api.ProtocolTCP, SwarmPort: 8080})
+	if len(updated) != 1 || updated[0].SwarmPort != 8080 {
+		t.Fatalf("expected replace in place, got %+v", updated)
+	}
+
+	updated, err := RemovePort(updated, "80/tcp")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updated) != 0 {
+		t.Fatalf("expected port removed, got %+v", updated)
+	}
+}
+
+func TestAddRemoveEnv(t *testing.T) {
+	env := []string{"FOO=bar"}
+
+	env, err := AddEnv(env, "FOO=baz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(env) != 1 || env[0] != "FOO=baz" {
+		t.Fatalf("expected FOO replaced, got %v", env)
+	}
+
+	env, err = AddEnv(env, "BAR=qux")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(env) != 2 {
+		t.Fatalf("expected 2 entries, got %v", env)
+	}
+
+	env = RemoveEnv(env, "FOO")
+	if len(env) != 1 || env[0] != "BAR=qux" {
+		t.Fatalf("expected FOO removed, got %v", env)
+	}
+}
+
+func TestAddRemoveNetwork(t *testing.T) {
+	var networks []*api.ServiceSpec_NetworkAttachmentConfig
+
+	networks = AddNetwork(networks, "net1")
+	networks = AddNetwork(networks, "net1")
+	if len(networks) != 1 {
+		t.Fatalf("expected no duplicate attachment, got %v", networks)
+	}
+
+	networks = RemoveNetwork(networks, "net1")
+	if len(networks) != 0 {
+		t.Fatalf("expected network removed, got %v", networks)
+	}
+}
+
+func TestAddRemoveConstraint(t *testing.T) {
+	constraints := []string{"node.role==manager"}
+
+	constraints, err := AddConstraint(constraints, "node.role==worker")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(constraints) != 1 || constraints[0] != "node.role==worker" {
+		t.Fatalf("expected constraint replaced, got %v", constraints)
+	}
+
+	constraints, err = RemoveConstraint(constraints, "node.role==worker")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(constraints) != 0 {
+		t.Fatalf("expected constraint removed, got %v", constraints)
+	}
+}
+
+func TestAddRemoveLabel(t *testing.T) {
+	labels, err := AddLabel(nil, "foo=bar")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if labels["foo"] != "bar" {
+		t.Fatalf("expected label set, got %v", labels)
+	}
+
+	labels = RemoveLabel(labels, "foo")
+	if _, ok := labels["foo"]; ok {
+		t.Fatalf("expected label removed, got %v", labels)
+	}
+}
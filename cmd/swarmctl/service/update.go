@@ -0,0 +1,313 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/docker/swarmkit/api"
+	"github.com/docker/swarmkit/cmd/swarmctl/common"
+	"github.com/docker/swarmkit/cmd/swarmctl/network"
+	"github.com/docker/swarmkit/cmd/swarmctl/service/serviceopts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/net/context"
+)
+
+var (
+	updateCmd = &cobra.Command{
+		Use:   "update <service ID>",
+		Short: "Update a service",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("update command takes a single service ID")
+			}
+
+			flags := cmd.Flags()
+			c, err := common.Dial(cmd)
+			if err != nil {
+				return err
+			}
+			ctx := common.Context(cmd)
+
+			svc, err := getService(ctx, c, args[0])
+			if err != nil {
+				return err
+			}
+			spec := &svc.Spec
+			container := spec.Task.GetContainer()
+			wantsContainer := flags.Changed("image") || flags.Changed("arg") ||
+				flags.Changed("env-add") || flags.Changed("env-rm")
+			if container == nil && wantsContainer {
+				return fmt.Errorf("--image, --arg, --env-add and --env-rm only apply to container tasks")
+			}
+
+			if flags.Changed("image") {
+				image, err := flags.GetString("image")
+				if err != nil {
+					return err
+				}
+				container.Image = image
+			}
+
+			if flags.Changed("arg") {
+				containerArgs, err := flags.GetStringSlice("arg")
+				if err != nil {
+					return err
+				}
+				container.Args = containerArgs
+			}
+
+			if flags.Changed("replicas") {
+				replicas, err := flags.GetUint64("replicas")
+				if err != nil {
+					return err
+				}
+				replicated, ok := spec.Mode.(*api.ServiceSpec_Replicated)
+				if !ok {
+					return fmt.Errorf("--replicas only applies to replicated services")
+				}
+				replicated.Replicated.Instances = replicas
+			}
+
+			if err := applyEnv(flags, container); err != nil {
+				return err
+			}
+			if err := applyPublish(flags, spec); err != nil {
+				return err
+			}
+			if err := applyLabels(flags, spec); err != nil {
+				return err
+			}
+			if err := applyConstraints(flags, spec); err != nil {
+				return err
+			}
+			if err := applyNetworks(ctx, c, flags, spec); err != nil {
+				return err
+			}
+
+			if force, err := flags.GetBool("force"); err != nil {
+				return err
+			} else if force {
+				spec.Task.ForceUpdate++
+			}
+
+			var image string
+			if container != nil {
+				image = container.Image
+			}
+			encodedAuth, err := resolveRegistryAuth(flags, image)
+			if err != nil {
+				return err
+			}
+
+			_, err = c.UpdateService(ctx, &api.UpdateServiceRequest{
+				ServiceID:           svc.ID,
+				ServiceVersion:      &svc.Meta.Version,
+				Spec:                spec,
+				EncodedRegistryAuth: encodedAuth,
+			})
+			return err
+		},
+	}
+)
+
+func applyEnv(flags *pflag.FlagSet, container *api.ContainerSpec) error {
+	if flags.Changed("env-add") {
+		adds, err := flags.GetStringSlice("env-add")
+		if err != nil {
+			return err
+		}
+		for _, raw := range adds {
+			env, err := serviceopts.AddEnv(container.Env, raw)
+			if err != nil {
+				return err
+			}
+			container.Env = env
+		}
+	}
+
+	if flags.Changed("env-rm") {
+		removes, err := flags.GetStringSlice("env-rm")
+		if err != nil {
+			return err
+		}
+		for _, key := range removes {
+			container.Env = serviceopts.RemoveEnv(container.Env, key)
+		}
+	}
+
+	return nil
+}
+
+func applyPublish(flags *pflag.FlagSet, spec *api.ServiceSpec) error {
+	if flags.Changed("publish-add") {
+		adds, err := flags.GetStringSlice("publish-add")
+		if err != nil {
+			return err
+		}
+		for _, raw := range adds {
+			port, err := serviceopts.ParsePort(raw)
+			if err != nil {
+				return err
+			}
+			if spec.Endpoint == nil {
+				spec.Endpoint = &api.EndpointSpec{}
+			}
+			spec.Endpoint.ExposedPorts = serviceopts.AddPort(spec.Endpoint.ExposedPorts, port)
+		}
+	}
+
+	if flags.Changed("publish-rm") {
+		removes, err := flags.GetStringSlice("publish-rm")
+		if err != nil {
+			return err
+		}
+		if spec.Endpoint != nil {
+			for _, raw := range removes {
+				ports, err := serviceopts.RemovePort(spec.Endpoint.ExposedPorts, raw)
+				if err != nil {
+					return err
+				}
+				spec.Endpoint.ExposedPorts = ports
+			}
+		}
+	}
+
+	return nil
+}
+
+func applyLabels(flags *pflag.FlagSet, spec *api.ServiceSpec) error {
+	if flags.Changed("label-add") {
+		adds, err := flags.GetStringSlice("label-add")
+		if err != nil {
+			return err
+		}
+		for _, raw := range adds {
+			labels, err := serviceopts.AddLabel(spec.Annotations.Labels, raw)
+			if err != nil {
+				return err
+			}
+			spec.Annotations.Labels = labels
+		}
+	}
+
+	if flags.Changed("label-rm") {
+		removes, err := flags.GetStringSlice("label-rm")
+		if err != nil {
+			return err
+		}
+		for _, key := range removes {
+			spec.Annotations.Labels = serviceopts.RemoveLabel(spec.Annotations.Labels, key)
+		}
+	}
+
+	return nil
+}
+
+func applyConstraints(flags *pflag.FlagSet, spec *api.ServiceSpec) error {
+	if flags.Changed("constraint-add") {
+		if spec.Task.Placement == nil {
+			spec.Task.Placement = &api.Placement{}
+		}
+
+		adds, err := flags.GetStringSlice("constraint-add")
+		if err != nil {
+			return err
+		}
+		for _, raw := range adds {
+			constraints, err := serviceopts.AddConstraint(spec.Task.Placement.Constraints, raw)
+			if err != nil {
+				return err
+			}
+			spec.Task.Placement.Constraints = constraints
+		}
+	}
+
+	if flags.Changed("constraint-rm") {
+		if spec.Task.Placement == nil {
+			spec.Task.Placement = &api.Placement{}
+		}
+
+		removes, err := flags.GetStringSlice("constraint-rm")
+		if err != nil {
+			return err
+		}
+		for _, raw := range removes {
+			constraints, err := serviceopts.RemoveConstraint(spec.Task.Placement.Constraints, raw)
+			if err != nil {
+				return err
+			}
+			spec.Task.Placement.Constraints = constraints
+		}
+	}
+
+	return nil
+}
+
+func applyNetworks(ctx context.Context, c api.ControlClient, flags *pflag.FlagSet, spec *api.ServiceSpec) error {
+	if flags.Changed("network-add") {
+		adds, err := flags.GetStringSlice("network-add")
+		if err != nil {
+			return err
+		}
+		for _, name := range adds {
+			n, err := network.GetNetwork(ctx, c, name)
+			if err != nil {
+				return err
+			}
+			spec.Networks = serviceopts.AddNetwork(spec.Networks, n.ID)
+		}
+	}
+
+	if flags.Changed("network-rm") {
+		removes, err := flags.GetStringSlice("network-rm")
+		if err != nil {
+			return err
+		}
+		for _, name := range removes {
+			n, err := network.GetNetwork(ctx, c, name)
+			if err != nil {
+				return err
+			}
+			spec.Networks = serviceopts.RemoveNetwork(spec.Networks, n.ID)
+		}
+	}
+
+	return nil
+}
+
+// getService resolves a service by ID or name.
+func getService(ctx context.Context, c api.ControlClient, input string) (*api.Service, error) {
+	r, err := c.GetService(ctx, &api.GetServiceRequest{ServiceID: input})
+	if err == nil {
+		return r.Service, nil
+	}
+
+	resp, err := c.ListServices(ctx, &api.ListServicesRequest{
+		Filters: &api.ListServicesRequest_Filters{Names: []string{input}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Services) == 0 {
+		return nil, fmt.Errorf("service %s not found", input)
+	}
+	return resp.Services[0], nil
+}
+
+func init() {
+	updateCmd.Flags().String("image", "", "Image")
+	updateCmd.Flags().StringSlice("arg", nil, "Container args")
+	updateCmd.Flags().Uint64("replicas", 0, "Number of instances for the service")
+	updateCmd.Flags().StringSlice("env-add", nil, "Add or update an environment variable")
+	updateCmd.Flags().StringSlice("env-rm", nil, "Remove an environment variable by key")
+	updateCmd.Flags().StringSlice("publish-add", nil, "Add or update a published port")
+	updateCmd.Flags().StringSlice("publish-rm", nil, "Remove a published port by target[/protocol]")
+	updateCmd.Flags().StringSlice("network-add", nil, "Attach a network by name")
+	updateCmd.Flags().StringSlice("network-rm", nil, "Detach a network by name")
+	updateCmd.Flags().StringSlice("constraint-add", nil, "Add or update a placement constraint")
+	updateCmd.Flags().StringSlice("constraint-rm", nil, "Remove the placement constraint on the key of a key==value or key!=value expression")
+	updateCmd.Flags().StringSlice("label-add", nil, "Add or update a label")
+	updateCmd.Flags().StringSlice("label-rm", nil, "Remove a label by key")
+	updateCmd.Flags().Bool("with-registry-auth", false, "Send registry authentication details to swarm agents")
+	updateCmd.Flags().Bool("force", false, "Force a restart of the service, bumping its task force-update counter")
+}
@@ -0,0 +1,249 @@
+package stack
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/docker/swarmkit/api"
+	"github.com/docker/swarmkit/cmd/swarmctl/common"
+	"github.com/docker/swarmkit/cmd/swarmctl/compose"
+	"github.com/docker/swarmkit/cmd/swarmctl/network"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var deployCmd = &cobra.Command{
+	Use:     "deploy <stack name>",
+	Aliases: []string{"up"},
+	Short:   "Deploy a stack from a Compose file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("deploy command takes a single stack name")
+		}
+		namespace := args[0]
+
+		flags := cmd.Flags()
+		file, err := flags.GetString("compose-file")
+		if err != nil {
+			return err
+		}
+		if file == "" {
+			return fmt.Errorf("--compose-file is mandatory")
+		}
+
+		cfg, err := compose.LoadFile(file)
+		if err != nil {
+			return err
+		}
+
+		c, err := common.Dial(cmd)
+		if err != nil {
+			return err
+		}
+		ctx := common.Context(cmd)
+
+		converter := compose.NewConverter(namespace)
+
+		networkIDs, err := deployNetworks(ctx, c, converter, cfg)
+		if err != nil {
+			return err
+		}
+
+		return deployServices(ctx, c, converter, cfg, networkIDs)
+	},
+}
+
+// deployNetworks creates any networks in cfg that don't already exist under
+// this stack's namespace, and returns a map of namespaced network name to
+// network ID covering the newly-created, already-existing and external
+// networks, for deployServices to resolve network attachments by ID.
+func deployNetworks(ctx context.Context, c api.ControlClient, converter *compose.Converter, cfg *compose.Config) (map[string]string, error) {
+	networks, err := converter.Networks(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := c.ListNetworks(ctx, &api.ListNetworksRequest{
+		Filters: &api.ListNetworksRequest_Filters{
+			Names: namesOf(networks),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	networkIDs := map[string]string{}
+	for _, n := range existing.Networks {
+		networkIDs[n.Spec.Annotations.Name] = n.ID
+	}
+
+	for name, spec := range networks {
+		if _, ok := networkIDs[name]; ok {
+			continue
+		}
+		resp, err := c.CreateNetwork(ctx, &api.CreateNetworkRequest{Spec: spec})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create network %s: %v", name, err)
+		}
+		networkIDs[name] = resp.Network.ID
+	}
+
+	// External networks are not created but looked up by name, and recorded
+	// under the namespaced name Converter.Services resolves attachments by.
+	for name, n := range cfg.Networks {
+		if !n.External.External {
+			continue
+		}
+		lookup := name
+		if n.External.Name != "" {
+			lookup = n.External.Name
+		}
+		ext, err := network.GetNetwork(ctx, c, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve external network %s: %v", lookup, err)
+		}
+		networkIDs[converter.NetworkName(name)] = ext.ID
+	}
+
+	return networkIDs, nil
+}
+
+func deployServices(ctx context.Context, c api.ControlClient, converter *compose.Converter, cfg *compose.Config, networkIDs map[string]string) error {
+	specs, err := converter.Services(cfg, networkIDs)
+	if err != nil {
+		return err
+	}
+
+	existing, err := c.ListServices(ctx, &api.ListServicesRequest{
+		Filters: &api.ListServicesRequest_Filters{
+			Labels: map[string]string{compose.NamespaceLabel: converter.Namespace},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]*api.Service{}
+	for _, svc := range existing.Services {
+		byName[svc.Spec.Annotations.Name] = svc
+	}
+
+	for name, spec := range specs {
+		current, ok := byName[name]
+		if !ok {
+			if _, err := c.CreateService(ctx, &api.CreateServiceRequest{Spec: spec}); err != nil {
+				return fmt.Errorf("failed to create service %s: %v", name, err)
+			}
+			continue
+		}
+		delete(byName, name)
+
+		if specEqual(&current.Spec, spec) {
+			continue
+		}
+		if _, err := c.UpdateService(ctx, &api.UpdateServiceRequest{
+			ServiceID:      current.ID,
+			ServiceVersion: &current.Meta.Version,
+			Spec:           spec,
+		}); err != nil {
+			return fmt.Errorf("failed to update service %s: %v", name, err)
+		}
+	}
+
+	// Anything left in byName is namespaced to this stack but no longer
+	// present in the compose file, and should be removed.
+	for name, svc := range byName {
+		if _, err := c.RemoveService(ctx, &api.RemoveServiceRequest{ServiceID: svc.ID}); err != nil {
+			return fmt.Errorf("failed to remove stale service %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// specEqual reports whether current and spec agree on every field
+// Converter.Services sets. A full Spec.String() comparison would also catch
+// server-side defaults and normalization the manager adds when it echoes
+// current back, which would make every deploy of an unchanged Compose file
+// look "changed".
+func specEqual(current, spec *api.ServiceSpec) bool {
+	if current.Annotations.Name != spec.Annotations.Name {
+		return false
+	}
+	if !reflect.DeepEqual(current.Annotations.Labels, spec.Annotations.Labels) {
+		return false
+	}
+
+	currentContainer := current.Task.GetContainer()
+	specContainer := spec.Task.GetContainer()
+	if (currentContainer == nil) != (specContainer == nil) {
+		return false
+	}
+	if currentContainer != nil {
+		if currentContainer.Image != specContainer.Image {
+			return false
+		}
+		if !reflect.DeepEqual(currentContainer.Command, specContainer.Command) {
+			return false
+		}
+		if !reflect.DeepEqual(currentContainer.Args, specContainer.Args) {
+			return false
+		}
+		if !reflect.DeepEqual(currentContainer.Env, specContainer.Env) {
+			return false
+		}
+		if !reflect.DeepEqual(currentContainer.Mounts, specContainer.Mounts) {
+			return false
+		}
+	}
+
+	var currentPorts, specPorts []*api.PortConfig
+	if current.Endpoint != nil {
+		currentPorts = current.Endpoint.ExposedPorts
+	}
+	if spec.Endpoint != nil {
+		specPorts = spec.Endpoint.ExposedPorts
+	}
+	if !reflect.DeepEqual(currentPorts, specPorts) {
+		return false
+	}
+
+	if !reflect.DeepEqual(current.Networks, spec.Networks) {
+		return false
+	}
+	if !reflect.DeepEqual(current.Mode, spec.Mode) {
+		return false
+	}
+
+	var currentConstraints, specConstraints []string
+	if current.Task.Placement != nil {
+		currentConstraints = current.Task.Placement.Constraints
+	}
+	if spec.Task.Placement != nil {
+		specConstraints = spec.Task.Placement.Constraints
+	}
+	if !reflect.DeepEqual(currentConstraints, specConstraints) {
+		return false
+	}
+
+	if !reflect.DeepEqual(current.Task.Restart, spec.Task.Restart) {
+		return false
+	}
+	if !reflect.DeepEqual(current.Update, spec.Update) {
+		return false
+	}
+
+	return true
+}
+
+func namesOf(specs map[string]*api.NetworkSpec) []string {
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	return names
+}
+
+func init() {
+	deployCmd.Flags().StringP("compose-file", "f", "", "Compose file to deploy")
+}
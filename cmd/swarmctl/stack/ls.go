@@ -0,0 +1,51 @@
+package stack
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/docker/swarmkit/api"
+	"github.com/docker/swarmkit/cmd/swarmctl/common"
+	"github.com/docker/swarmkit/cmd/swarmctl/compose"
+	"github.com/spf13/cobra"
+)
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List stacks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			return fmt.Errorf("ls command takes no arguments")
+		}
+
+		c, err := common.Dial(cmd)
+		if err != nil {
+			return err
+		}
+
+		r, err := c.ListServices(common.Context(cmd), &api.ListServicesRequest{})
+		if err != nil {
+			return err
+		}
+
+		namespaces := map[string]int{}
+		for _, svc := range r.Services {
+			namespace, ok := svc.Spec.Annotations.Labels[compose.NamespaceLabel]
+			if !ok {
+				continue
+			}
+			namespaces[namespace]++
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		defer w.Flush()
+
+		fmt.Fprintln(w, "NAME\tSERVICES")
+		for namespace, count := range namespaces {
+			fmt.Fprintf(w, "%s\t%d\n", namespace, count)
+		}
+
+		return nil
+	},
+}
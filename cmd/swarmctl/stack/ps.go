@@ -0,0 +1,78 @@
+package stack
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/docker/swarmkit/api"
+	"github.com/docker/swarmkit/cmd/swarmctl/common"
+	"github.com/docker/swarmkit/cmd/swarmctl/compose"
+	"github.com/spf13/cobra"
+)
+
+var psCmd = &cobra.Command{
+	Use:   "ps <stack name>",
+	Short: "List the tasks in a stack",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("ps command takes a single stack name")
+		}
+		namespace := args[0]
+
+		c, err := common.Dial(cmd)
+		if err != nil {
+			return err
+		}
+		ctx := common.Context(cmd)
+
+		services, err := c.ListServices(ctx, &api.ListServicesRequest{
+			Filters: &api.ListServicesRequest_Filters{
+				Labels: map[string]string{compose.NamespaceLabel: namespace},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		serviceIDs := make([]string, 0, len(services.Services))
+		for _, svc := range services.Services {
+			serviceIDs = append(serviceIDs, svc.ID)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		defer w.Flush()
+
+		fmt.Fprintln(w, "ID\tSERVICE\tIMAGE\tNODE\tDESIRED STATE\tLAST STATE")
+
+		if len(serviceIDs) == 0 {
+			// An empty ServiceIDs filter means "no filter" to the manager, not
+			// "match nothing" — with zero services in this stack there are no
+			// tasks to show, so return before that filter turns into a dump of
+			// every task in the swarm.
+			return nil
+		}
+
+		tasks, err := c.ListTasks(ctx, &api.ListTasksRequest{
+			Filters: &api.ListTasksRequest_Filters{
+				ServiceIDs: serviceIDs,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks.Tasks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				task.ID,
+				task.ServiceAnnotations.Name,
+				task.Spec.GetContainer().Image,
+				task.NodeID,
+				task.DesiredState.String(),
+				task.Status.State.String(),
+			)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,58 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/docker/swarmkit/api"
+	"github.com/docker/swarmkit/cmd/swarmctl/common"
+	"github.com/docker/swarmkit/cmd/swarmctl/compose"
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:     "rm <stack name>",
+	Aliases: []string{"down"},
+	Short:   "Remove a stack",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("rm command takes a single stack name")
+		}
+		namespace := args[0]
+
+		c, err := common.Dial(cmd)
+		if err != nil {
+			return err
+		}
+		ctx := common.Context(cmd)
+
+		services, err := c.ListServices(ctx, &api.ListServicesRequest{
+			Filters: &api.ListServicesRequest_Filters{
+				Labels: map[string]string{compose.NamespaceLabel: namespace},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		for _, svc := range services.Services {
+			if _, err := c.RemoveService(ctx, &api.RemoveServiceRequest{ServiceID: svc.ID}); err != nil {
+				return fmt.Errorf("failed to remove service %s: %v", svc.Spec.Annotations.Name, err)
+			}
+		}
+
+		networks, err := c.ListNetworks(ctx, &api.ListNetworksRequest{
+			Filters: &api.ListNetworksRequest_Filters{
+				Labels: map[string]string{compose.NamespaceLabel: namespace},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		for _, n := range networks.Networks {
+			if _, err := c.RemoveNetwork(ctx, &api.RemoveNetworkRequest{NetworkID: n.ID}); err != nil {
+				return fmt.Errorf("failed to remove network %s: %v", n.Spec.Annotations.Name, err)
+			}
+		}
+
+		return nil
+	},
+}
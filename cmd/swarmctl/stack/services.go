@@ -0,0 +1,47 @@
+package stack
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/docker/swarmkit/api"
+	"github.com/docker/swarmkit/cmd/swarmctl/common"
+	"github.com/docker/swarmkit/cmd/swarmctl/compose"
+	"github.com/spf13/cobra"
+)
+
+var servicesCmd = &cobra.Command{
+	Use:   "services <stack name>",
+	Short: "List the services in a stack",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("services command takes a single stack name")
+		}
+		namespace := args[0]
+
+		c, err := common.Dial(cmd)
+		if err != nil {
+			return err
+		}
+
+		r, err := c.ListServices(common.Context(cmd), &api.ListServicesRequest{
+			Filters: &api.ListServicesRequest_Filters{
+				Labels: map[string]string{compose.NamespaceLabel: namespace},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		defer w.Flush()
+
+		fmt.Fprintln(w, "ID\tNAME\tIMAGE")
+		for _, svc := range r.Services {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", svc.ID, svc.Spec.Annotations.Name, svc.Spec.Task.GetContainer().Image)
+		}
+
+		return nil
+	},
+}
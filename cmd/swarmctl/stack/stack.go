@@ -0,0 +1,22 @@
+package stack
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Command is the `swarmctl stack` subcommand root; it groups everything
+// needed to deploy and inspect a Compose-file-defined stack.
+var Command = &cobra.Command{
+	Use:   "stack",
+	Short: "Deploy and manage stacks",
+}
+
+func init() {
+	Command.AddCommand(
+		deployCmd,
+		lsCmd,
+		psCmd,
+		rmCmd,
+		servicesCmd,
+	)
+}